@@ -0,0 +1,57 @@
+package main
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/HealthNoteLabs/HealthNote-Relay/relay/pkg/buildinfo"
+	"github.com/HealthNoteLabs/HealthNote-Relay/relay/pkg/config"
+)
+
+var landingTemplate = template.Must(template.New("landing").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Relay.Name}}</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 40rem; margin: 3rem auto; padding: 0 1rem; line-height: 1.5; color: #222; }
+code { background: #f2f2f2; padding: 0 .25rem; word-break: break-all; }
+</style>
+</head>
+<body>
+<h1>{{.Relay.Name}}</h1>
+<p>{{.Relay.Description}}</p>
+{{- with .Relay}}
+{{- if or .Contact .Pubkey}}
+<h2>Operator</h2>
+<ul>
+{{- if .Contact}}
+<li>Contact: {{.Contact}}</li>
+{{- end}}
+{{- if .Pubkey}}
+<li>Pubkey: <code>{{.Pubkey}}</code></li>
+{{- end}}
+</ul>
+{{- end}}
+{{- end}}
+<p><small>Version {{.Build.Version}}{{with .Build.Commit}} ({{.}}){{end}}</small></p>
+</body>
+</html>
+`))
+
+// landingHandler serves a human-readable page describing the relay at /,
+// built from the same relay info as the rest of the configuration.
+func landingHandler(info config.RelayInfo) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		data := struct {
+			Relay config.RelayInfo
+			Build buildinfo.Info
+		}{info, buildinfo.Get()}
+		if err := landingTemplate.Execute(w, data); err != nil {
+			log.Printf("Failed to render landing page: %v", err)
+		}
+	}
+}
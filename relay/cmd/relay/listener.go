@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"strconv"
+
+	"github.com/HealthNoteLabs/HealthNote-Relay/relay/pkg/config"
+)
+
+// listenFDsStart is the first file descriptor passed by systemd socket
+// activation (SD_LISTEN_FDS_START).
+const listenFDsStart = 3
+
+// listen returns the relay's listening sockets. Sockets inherited through
+// systemd socket activation take precedence over the configured
+// addresses; otherwise each address is bound directly, with SO_REUSEPORT
+// when enabled so a new binary can bind alongside the old one during an
+// upgrade.
+func listen(ctx context.Context, cfg *config.Config) ([]net.Listener, error) {
+	lns, err := activationListeners()
+	if err != nil || lns != nil {
+		return lns, err
+	}
+
+	var lc net.ListenConfig
+	if cfg.ReusePort {
+		lc.Control = reusePortControl
+	}
+	for _, addr := range cfg.Listen {
+		ln, err := lc.Listen(ctx, listenNetwork(addr), addr)
+		if err != nil {
+			closeAll(lns)
+			return nil, err
+		}
+		lns = append(lns, ln)
+	}
+	return lns, nil
+}
+
+// listenNetwork picks the network for addr. Literal IPs bind only their
+// own family ("tcp6" sockets are IPv6-only), so an IPv4 and an IPv6
+// wildcard on the same port do not collide. Anything else, including a
+// bare ":port", uses "tcp" and is dual-stack where the OS allows it.
+func listenNetwork(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "tcp"
+	}
+	ip, err := netip.ParseAddr(host)
+	switch {
+	case err != nil:
+		return "tcp"
+	case ip.Unmap().Is4():
+		// IPv4-mapped IPv6 literals (::ffff:a.b.c.d) can only bind as IPv4.
+		return "tcp4"
+	default:
+		return "tcp6"
+	}
+}
+
+// activationListeners returns the sockets passed by systemd, or nil when
+// the process was not socket activated.
+func activationListeners() ([]net.Listener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	fds, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || fds < 1 {
+		return nil, nil
+	}
+
+	// Keep the variables from leaking into child processes.
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	var lns []net.Listener
+	for fd := listenFDsStart; fd < listenFDsStart+fds; fd++ {
+		f := os.NewFile(uintptr(fd), "systemd-socket-"+strconv.Itoa(fd))
+		ln, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			closeAll(lns)
+			return nil, fmt.Errorf("failed to use activated socket %d: %w", fd, err)
+		}
+		lns = append(lns, ln)
+	}
+	return lns, nil
+}
+
+func closeAll(lns []net.Listener) {
+	for _, ln := range lns {
+		ln.Close()
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net"
+	"testing"
+)
+
+func TestListenNetwork(t *testing.T) {
+	tests := []struct {
+		addr     string
+		expected string
+	}{
+		{":8080", "tcp"},
+		{"localhost:8080", "tcp"},
+		{"0.0.0.0:8080", "tcp4"},
+		{"127.0.0.1:8080", "tcp4"},
+		{"[::]:8080", "tcp6"},
+		{"[::1]:8080", "tcp6"},
+		{"[fe80::1%eth0]:8080", "tcp6"},
+		{"[::ffff:127.0.0.1]:8080", "tcp4"},
+		{"not-an-address", "tcp"},
+	}
+
+	for _, tt := range tests {
+		if got := listenNetwork(tt.addr); got != tt.expected {
+			t.Errorf("listenNetwork(%q) = %s, want %s", tt.addr, got, tt.expected)
+		}
+	}
+}
+
+func TestListenIPv4Mapped(t *testing.T) {
+	addr := "[::ffff:127.0.0.1]:0"
+	ln, err := net.Listen(listenNetwork(addr), addr)
+	if err != nil {
+		t.Fatalf("failed to listen on %s: %v", addr, err)
+	}
+	ln.Close()
+}
+
+func TestListensOn(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	if !listensOn([]net.Listener{ln}, port) {
+		t.Errorf("listensOn(%d) = false for a listener on that port", port)
+	}
+	if listensOn([]net.Listener{ln}, 443) {
+		t.Error("listensOn(443) = true for a listener on another port")
+	}
+	if listensOn(nil, 443) {
+		t.Error("listensOn(443) = true with no listeners")
+	}
+}
@@ -1,11 +1,54 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/HealthNoteLabs/HealthNote-Relay/relay/pkg/blossom"
+	"github.com/HealthNoteLabs/HealthNote-Relay/relay/pkg/buildinfo"
+	"github.com/HealthNoteLabs/HealthNote-Relay/relay/pkg/config"
 )
 
 func main() {
-	log.Println("Health & Fitness Relay for Nostr starting...")
-	log.Fatal(http.ListenAndServe(":8080", nil))
-} 
\ No newline at end of file
+	configPath := flag.String("config", os.Getenv("RELAY_CONFIG"), "path to a YAML config file")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	if cfg.Verbose {
+		log.SetFlags(log.LstdFlags | log.Lmicroseconds | log.Lshortfile)
+	}
+
+	log.Printf("Health & Fitness Relay for Nostr %s starting (profile %s)...", buildinfo.Get().Version, cfg.Profile)
+	mux := http.NewServeMux()
+	mux.Handle("GET /{$}", landingHandler(cfg.Relay))
+	mux.HandleFunc("GET /version", versionHandler)
+
+	if cfg.Blossom.Enabled {
+		store, err := blossom.NewDiskStore(cfg.Blossom.Dir)
+		if err != nil {
+			log.Fatalf("Failed to open Blossom store: %v", err)
+		}
+		blossom.NewServer(store, blossom.Options{
+			BaseURL:       cfg.Blossom.BaseURL,
+			MaxUploadSize: cfg.Blossom.MaxUploadSize,
+			PublicRead:    cfg.Blossom.PublicRead,
+			Allow:         cfg.Blossom.Allow,
+		}).Register(mux)
+		log.Printf("Embedded Blossom server enabled, storing blobs in %s", cfg.Blossom.Dir)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := serve(ctx, cfg, mux); err != nil {
+		log.Fatal(err)
+	}
+}
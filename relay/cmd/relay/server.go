@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+
+	"github.com/HealthNoteLabs/HealthNote-Relay/relay/pkg/config"
+)
+
+// shutdownTimeout bounds how long in-flight requests may take to finish
+// once the relay is asked to stop.
+const shutdownTimeout = 30 * time.Second
+
+// serve runs an HTTP server for handler on every listening socket, using
+// TLS when it is configured, until ctx is cancelled. It then stops
+// accepting connections and waits for in-flight requests, so a
+// replacement process that shares or inherited the sockets can take over
+// without refusing clients.
+func serve(ctx context.Context, cfg *config.Config, handler http.Handler) error {
+	lns, err := listen(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	var certFile, keyFile string
+	switch {
+	case cfg.TLS.Autocert.Hostname != "":
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.TLS.Autocert.Hostname),
+			Cache:      autocert.DirCache(cfg.TLS.Autocert.CacheDir),
+			Email:      cfg.TLS.Autocert.Email,
+		}
+		srv.TLSConfig = manager.TLSConfig()
+		log.Printf("Using automatic TLS certificates for %s", cfg.TLS.Autocert.Hostname)
+		if !listensOn(lns, 443) {
+			// The CA sends TLS-ALPN-01 challenges to port 443. That is fine
+			// when a firewall or container maps 443 to this port, so only warn.
+			log.Println("Warning: autocert is enabled but no listener is on port 443; certificate issuance will fail unless port 443 is forwarded to the relay")
+		}
+	case cfg.TLS.CertFile != "":
+		certFile, keyFile = cfg.TLS.CertFile, cfg.TLS.KeyFile
+		log.Printf("Using TLS certificate %s", certFile)
+	case cfg.Profile == config.ProfileProd:
+		log.Println("TLS is not configured; expecting a TLS-terminating proxy in front of the relay")
+	}
+
+	errc := make(chan error, len(lns))
+	for _, ln := range lns {
+		go func(ln net.Listener) {
+			if cfg.TLS.Enabled() {
+				log.Printf("Serving TLS on %s", ln.Addr())
+				errc <- srv.ServeTLS(ln, certFile, keyFile)
+			} else {
+				log.Printf("Serving on %s", ln.Addr())
+				errc <- srv.Serve(ln)
+			}
+		}(ln)
+	}
+
+	var serveErr error
+	select {
+	case serveErr = <-errc:
+		log.Printf("Listener failed, shutting down: %v", serveErr)
+	case <-ctx.Done():
+		log.Println("Shutting down, waiting for in-flight requests...")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil && serveErr == nil {
+		serveErr = err
+	}
+	return serveErr
+}
+
+// listensOn reports whether any of lns is bound to port. It checks the
+// bound sockets rather than the configuration so sockets inherited from
+// systemd count too.
+func listensOn(lns []net.Listener, port int) bool {
+	for _, ln := range lns {
+		if addr, ok := ln.Addr().(*net.TCPAddr); ok && addr.Port == port {
+			return true
+		}
+	}
+	return false
+}
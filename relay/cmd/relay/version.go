@@ -0,0 +1,14 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/HealthNoteLabs/HealthNote-Relay/relay/pkg/buildinfo"
+)
+
+// versionHandler serves the relay's build information as JSON.
+func versionHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(buildinfo.Get())
+}
@@ -0,0 +1,31 @@
+// Package nostrtest holds helpers for tests that need signed Nostr events.
+package nostrtest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// AuthHeader signs an event of kind with tags as sk and returns it in the
+// "Nostr <base64 event>" form used by NIP-98 and Blossom Authorization
+// headers.
+func AuthHeader(t testing.TB, sk string, kind int, createdAt time.Time, tags nostr.Tags) string {
+	t.Helper()
+	evt := nostr.Event{
+		Kind:      kind,
+		CreatedAt: nostr.Timestamp(createdAt.Unix()),
+		Tags:      tags,
+	}
+	if err := evt.Sign(sk); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(evt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "Nostr " + base64.StdEncoding.EncodeToString(data)
+}
@@ -0,0 +1,65 @@
+package blossom
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/HealthNoteLabs/HealthNote-Relay/relay/pkg/httpauth"
+)
+
+// KindAuthorization is the Blossom authorization event kind (BUD-01).
+const KindAuthorization = 24242
+
+// clockSkew tolerates authorization events created slightly in the future
+// by clients with a fast clock.
+const clockSkew = time.Minute
+
+// authorize validates the Blossom authorization event in r for verb and,
+// when hash is non-empty, checks that the event covers that blob. Upload
+// and delete events must name the blob in an x tag; get events may omit
+// x to cover any blob, as BUD-01 allows. It returns the pubkey that
+// signed the event.
+func authorize(r *http.Request, verb, hash string) (string, error) {
+	evt, err := httpauth.DecodeEvent(r)
+	if err != nil {
+		return "", err
+	}
+
+	if evt.Kind != KindAuthorization {
+		return "", errors.New("authorization event has the wrong kind")
+	}
+
+	now := time.Now()
+	if evt.CreatedAt.Time().After(now.Add(clockSkew)) {
+		return "", errors.New("authorization event is created in the future")
+	}
+
+	var verbOK, hasX, hashOK, expiresOK bool
+	for _, tag := range evt.Tags {
+		if len(tag) < 2 {
+			continue
+		}
+		switch tag[0] {
+		case "t":
+			verbOK = verbOK || tag[1] == verb
+		case "x":
+			hasX = true
+			hashOK = hashOK || tag[1] == hash
+		case "expiration":
+			expires, err := strconv.ParseInt(tag[1], 10, 64)
+			expiresOK = err == nil && time.Unix(expires, 0).After(now)
+		}
+	}
+
+	switch {
+	case !expiresOK:
+		return "", errors.New("authorization event is expired or has no expiration")
+	case !verbOK:
+		return "", errors.New("authorization event does not allow " + verb)
+	case hash != "" && !hashOK && (hasX || verb != "get"):
+		return "", errors.New("authorization event does not cover this blob")
+	}
+	return evt.PubKey, nil
+}
@@ -0,0 +1,159 @@
+package blossom
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/HealthNoteLabs/HealthNote-Relay/relay/internal/nostrtest"
+)
+
+const testHash = "b1674191a88ec5cdd733e4240a81803105dc412d6c6708d53ab94fc248f4f553"
+
+// blobAuth returns a valid authorization header for verb covering hashes.
+func blobAuth(t *testing.T, sk, verb string, hashes ...string) string {
+	t.Helper()
+	tags := nostr.Tags{{"t", verb}, expiration(time.Hour)}
+	for _, hash := range hashes {
+		tags = append(tags, nostr.Tag{"x", hash})
+	}
+	return nostrtest.AuthHeader(t, sk, KindAuthorization, time.Now(), tags)
+}
+
+func expiration(in time.Duration) nostr.Tag {
+	return nostr.Tag{"expiration", strconv.FormatInt(time.Now().Add(in).Unix(), 10)}
+}
+
+func TestAuthorize(t *testing.T) {
+	sk := nostr.GeneratePrivateKey()
+	pubkey, _ := nostr.GetPublicKey(sk)
+	now := time.Now()
+	otherHash := "0000000000000000000000000000000000000000000000000000000000000000"
+
+	tests := []struct {
+		name    string
+		header  string
+		verb    string
+		hash    string
+		wantErr bool
+	}{
+		{
+			name:   "valid upload",
+			header: nostrtest.AuthHeader(t, sk, KindAuthorization, now, nostr.Tags{{"t", "upload"}, {"x", testHash}, expiration(time.Hour)}),
+			verb:   "upload",
+			hash:   testHash,
+		},
+		{
+			name:    "missing header",
+			verb:    "upload",
+			wantErr: true,
+		},
+		{
+			name:    "wrong scheme",
+			header:  "Bearer abc",
+			verb:    "upload",
+			wantErr: true,
+		},
+		{
+			name:    "wrong kind",
+			header:  nostrtest.AuthHeader(t, sk, 27235, now, nostr.Tags{{"t", "upload"}, {"x", testHash}, expiration(time.Hour)}),
+			verb:    "upload",
+			hash:    testHash,
+			wantErr: true,
+		},
+		{
+			name:    "expired",
+			header:  nostrtest.AuthHeader(t, sk, KindAuthorization, now, nostr.Tags{{"t", "upload"}, {"x", testHash}, expiration(-time.Minute)}),
+			verb:    "upload",
+			hash:    testHash,
+			wantErr: true,
+		},
+		{
+			name:    "missing expiration",
+			header:  nostrtest.AuthHeader(t, sk, KindAuthorization, now, nostr.Tags{{"t", "upload"}, {"x", testHash}}),
+			verb:    "upload",
+			hash:    testHash,
+			wantErr: true,
+		},
+		{
+			name:    "wrong verb",
+			header:  nostrtest.AuthHeader(t, sk, KindAuthorization, now, nostr.Tags{{"t", "get"}, {"x", testHash}, expiration(time.Hour)}),
+			verb:    "delete",
+			hash:    testHash,
+			wantErr: true,
+		},
+		{
+			name:    "x mismatch",
+			header:  nostrtest.AuthHeader(t, sk, KindAuthorization, now, nostr.Tags{{"t", "delete"}, {"x", otherHash}, expiration(time.Hour)}),
+			verb:    "delete",
+			hash:    testHash,
+			wantErr: true,
+		},
+		{
+			name:    "upload without x",
+			header:  nostrtest.AuthHeader(t, sk, KindAuthorization, now, nostr.Tags{{"t", "upload"}, expiration(time.Hour)}),
+			verb:    "upload",
+			hash:    testHash,
+			wantErr: true,
+		},
+		{
+			name:   "get without x",
+			header: nostrtest.AuthHeader(t, sk, KindAuthorization, now, nostr.Tags{{"t", "get"}, expiration(time.Hour)}),
+			verb:   "get",
+			hash:   testHash,
+		},
+		{
+			name:    "get for another blob",
+			header:  nostrtest.AuthHeader(t, sk, KindAuthorization, now, nostr.Tags{{"t", "get"}, {"x", otherHash}, expiration(time.Hour)}),
+			verb:    "get",
+			hash:    testHash,
+			wantErr: true,
+		},
+		{
+			name:    "created in the future",
+			header:  nostrtest.AuthHeader(t, sk, KindAuthorization, now.Add(10*time.Minute), nostr.Tags{{"t", "upload"}, {"x", testHash}, expiration(time.Hour)}),
+			verb:    "upload",
+			hash:    testHash,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			got, err := authorize(r, tt.verb, tt.hash)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("authorize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got != pubkey {
+				t.Errorf("authorize() pubkey = %s, want %s", got, pubkey)
+			}
+		})
+	}
+}
+
+func TestAuthorizeRejectsTamperedEvent(t *testing.T) {
+	sk := nostr.GeneratePrivateKey()
+	evt := nostr.Event{
+		Kind:      KindAuthorization,
+		CreatedAt: nostr.Now(),
+		Tags:      nostr.Tags{{"t", "get"}, expiration(time.Hour)},
+	}
+	evt.Sign(sk)
+	evt.Tags = append(evt.Tags, nostr.Tag{"x", testHash})
+	data, _ := json.Marshal(evt)
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Authorization", "Nostr "+base64.StdEncoding.EncodeToString(data))
+	if _, err := authorize(r, "get", testHash); err == nil {
+		t.Fatal("authorize() accepted an event whose tags changed after signing")
+	}
+}
@@ -0,0 +1,218 @@
+package blossom
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DiskStore keeps blobs as files named by their hash in a directory, each
+// with a <hash>.json descriptor alongside it.
+type DiskStore struct {
+	dir string
+	mu  sync.Mutex // serializes descriptor updates
+}
+
+// NewDiskStore returns a DiskStore rooted at dir, creating it if needed.
+func NewDiskStore(dir string) (*DiskStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create blob directory: %w", err)
+	}
+	return &DiskStore{dir: dir}, nil
+}
+
+func (s *DiskStore) Put(ctx context.Context, r io.Reader, owner, contentType string, verify func(hash string) error) (*Blob, error) {
+	tmp, err := os.CreateTemp(s.dir, ".upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	hash := hex.EncodeToString(h.Sum(nil))
+	if verify != nil {
+		if err := verify(hash); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	blob, err := s.readBlob(hash)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		blob = &Blob{
+			SHA256:   hash,
+			Size:     size,
+			Type:     contentType,
+			Uploaded: time.Now().Unix(),
+		}
+	case err != nil:
+		return nil, err
+	}
+
+	// Write the content whenever it is missing, which also repairs a
+	// descriptor left behind without its file.
+	if _, err := os.Stat(s.blobPath(hash)); errors.Is(err, fs.ErrNotExist) {
+		if err := os.Rename(tmp.Name(), s.blobPath(hash)); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	if !blob.OwnedBy(owner) {
+		blob.Owners = append(blob.Owners, owner)
+	}
+	if blob.Type == "" {
+		blob.Type = contentType
+	}
+	if err := s.writeBlob(blob); err != nil {
+		return nil, err
+	}
+	return blob, nil
+}
+
+func (s *DiskStore) Get(ctx context.Context, hash string) (*Blob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.readBlob(hash)
+}
+
+func (s *DiskStore) Open(ctx context.Context, hash string) (io.ReadSeekCloser, error) {
+	f, err := os.Open(s.blobPath(hash))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (s *DiskStore) Delete(ctx context.Context, hash, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	blob, err := s.readBlob(hash)
+	if err != nil {
+		return err
+	}
+	if !blob.OwnedBy(owner) {
+		return ErrNotFound
+	}
+
+	owners := blob.Owners[:0]
+	for _, o := range blob.Owners {
+		if o != owner {
+			owners = append(owners, o)
+		}
+	}
+	blob.Owners = owners
+	if len(blob.Owners) > 0 {
+		return s.writeBlob(blob)
+	}
+
+	// Drop the descriptor first: a crash afterwards leaves an orphan file
+	// that the next upload of the same blob reuses, rather than a
+	// descriptor pointing at nothing.
+	if err := os.Remove(s.metaPath(hash)); err != nil {
+		return err
+	}
+	if err := os.Remove(s.blobPath(hash)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// List scans every descriptor in the directory, which is fine for the
+// small, single-node deployments DiskStore is meant for.
+func (s *DiskStore) List(ctx context.Context, owner string) ([]*Blob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var blobs []*Blob
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		blob, err := s.readBlob(strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			return nil, err
+		}
+		if blob.OwnedBy(owner) {
+			blobs = append(blobs, blob)
+		}
+	}
+	return blobs, nil
+}
+
+func (s *DiskStore) blobPath(hash string) string {
+	return filepath.Join(s.dir, hash)
+}
+
+func (s *DiskStore) metaPath(hash string) string {
+	return filepath.Join(s.dir, hash+".json")
+}
+
+func (s *DiskStore) readBlob(hash string) (*Blob, error) {
+	data, err := os.ReadFile(s.metaPath(hash))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var blob Blob
+	if err := json.Unmarshal(data, &blob); err != nil {
+		return nil, fmt.Errorf("corrupt descriptor for %s: %w", hash, err)
+	}
+	return &blob, nil
+}
+
+// writeBlob replaces the descriptor atomically so a crash cannot leave a
+// truncated file behind.
+func (s *DiskStore) writeBlob(blob *Blob) error {
+	data, err := json.Marshal(blob)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".meta-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.metaPath(blob.SHA256))
+}
@@ -0,0 +1,139 @@
+package blossom
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func newDiskStore(t *testing.T) *DiskStore {
+	t.Helper()
+	store, err := NewDiskStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestDiskStoreOwners(t *testing.T) {
+	ctx := context.Background()
+	store := newDiskStore(t)
+
+	first, err := store.Put(ctx, strings.NewReader("hello"), "alice", "text/plain", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := store.Put(ctx, strings.NewReader("hello"), "bob", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.SHA256 != second.SHA256 {
+		t.Fatalf("same content stored under %s and %s", first.SHA256, second.SHA256)
+	}
+	if !second.OwnedBy("alice") || !second.OwnedBy("bob") || len(second.Owners) != 2 {
+		t.Fatalf("owners = %v, want alice and bob", second.Owners)
+	}
+	if second.Type != "text/plain" {
+		t.Errorf("type = %q, want the first uploader's text/plain", second.Type)
+	}
+
+	if _, err := store.Put(ctx, strings.NewReader("other"), "alice", "", nil); err != nil {
+		t.Fatal(err)
+	}
+	for owner, want := range map[string]int{"alice": 2, "bob": 1, "carol": 0} {
+		blobs, err := store.List(ctx, owner)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(blobs) != want {
+			t.Errorf("List(%s) returned %d blobs, want %d", owner, len(blobs), want)
+		}
+	}
+
+	hash := first.SHA256
+	if err := store.Delete(ctx, hash, "carol"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete by non-owner error = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete(ctx, hash, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	blob, err := store.Get(ctx, hash)
+	if err != nil {
+		t.Fatalf("blob removed while bob still owns it: %v", err)
+	}
+	if blob.OwnedBy("alice") {
+		t.Error("alice still owns the blob after deleting it")
+	}
+	if err := store.Delete(ctx, hash, "alice"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete by alice error = %v, want ErrNotFound", err)
+	}
+
+	if err := store.Delete(ctx, hash, "bob"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(ctx, hash); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after last owner deleted error = %v, want ErrNotFound", err)
+	}
+	if _, err := os.Stat(store.blobPath(hash)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("content file still present after last owner deleted it")
+	}
+}
+
+func TestDiskStorePutVerify(t *testing.T) {
+	ctx := context.Background()
+	store := newDiskStore(t)
+	errRejected := errors.New("rejected")
+
+	var seen string
+	_, err := store.Put(ctx, strings.NewReader("hello"), "alice", "", func(hash string) error {
+		seen = hash
+		return errRejected
+	})
+	if !errors.Is(err, errRejected) {
+		t.Fatalf("Put error = %v, want the verify error", err)
+	}
+	if seen == "" {
+		t.Fatal("verify was not called with the blob hash")
+	}
+
+	entries, err := os.ReadDir(store.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("rejected upload left %d files behind", len(entries))
+	}
+}
+
+func TestDiskStorePutRestoresMissingContent(t *testing.T) {
+	ctx := context.Background()
+	store := newDiskStore(t)
+
+	blob, err := store.Put(ctx, strings.NewReader("hello"), "alice", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Simulate a crash that lost the content but kept the descriptor.
+	if err := os.Remove(store.blobPath(blob.SHA256)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Open(ctx, blob.SHA256); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Open of missing content error = %v, want ErrNotFound", err)
+	}
+
+	if _, err := store.Put(ctx, strings.NewReader("hello"), "alice", "", nil); err != nil {
+		t.Fatal(err)
+	}
+	f, err := store.Open(ctx, blob.SHA256)
+	if err != nil {
+		t.Fatalf("content not restored: %v", err)
+	}
+	defer f.Close()
+	data, _ := io.ReadAll(f)
+	if string(data) != "hello" {
+		t.Errorf("restored content = %q, want hello", data)
+	}
+}
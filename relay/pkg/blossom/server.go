@@ -0,0 +1,336 @@
+package blossom
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HealthNoteLabs/HealthNote-Relay/relay/pkg/httpauth"
+)
+
+// Options configures a Server.
+type Options struct {
+	// BaseURL is the public URL blobs are served under. When empty it is
+	// derived from each request's Host header.
+	BaseURL string
+
+	// MaxUploadSize caps the size of an uploaded blob in bytes.
+	MaxUploadSize int64
+
+	// PublicRead lets anyone fetch blobs and list a pubkey's uploads.
+	// Otherwise reads require an authorization event from an owner.
+	PublicRead bool
+
+	// Allow lists the pubkeys permitted to upload. An empty list admits
+	// any signer.
+	Allow []string
+}
+
+// Server serves the Blossom endpoints on top of a Store.
+type Server struct {
+	store Store
+	opts  Options
+}
+
+// descriptor is the blob descriptor returned to clients (BUD-02).
+type descriptor struct {
+	URL      string `json:"url"`
+	SHA256   string `json:"sha256"`
+	Size     int64  `json:"size"`
+	Type     string `json:"type,omitempty"`
+	Uploaded int64  `json:"uploaded"`
+}
+
+// NewServer returns a Server backed by store.
+func NewServer(store Store, opts Options) *Server {
+	return &Server{store: store, opts: opts}
+}
+
+// Register adds the Blossom routes to mux.
+func (s *Server) Register(mux *http.ServeMux) {
+	mux.HandleFunc("PUT /upload", s.cors(s.handleUpload))
+	mux.HandleFunc("GET /list/{pubkey}", s.cors(s.handleList))
+	mux.HandleFunc("GET /{blob}", s.cors(s.handleGet))
+	mux.HandleFunc("DELETE /{blob}", s.cors(s.handleDelete))
+
+	preflight := s.cors(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("OPTIONS /upload", preflight)
+	mux.HandleFunc("OPTIONS /list/{pubkey}", preflight)
+	mux.HandleFunc("OPTIONS /{blob}", preflight)
+}
+
+func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
+	pubkey, err := authorize(r, "upload", "")
+	if err != nil {
+		fail(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	if len(s.opts.Allow) > 0 && !slices.Contains(s.opts.Allow, pubkey) {
+		fail(w, http.StatusForbidden, "pubkey is not allowed to upload")
+		return
+	}
+
+	body := r.Body
+	if s.opts.MaxUploadSize > 0 {
+		if r.ContentLength > s.opts.MaxUploadSize {
+			fail(w, http.StatusRequestEntityTooLarge, "blob exceeds the maximum upload size")
+			return
+		}
+		body = http.MaxBytesReader(w, r.Body, s.opts.MaxUploadSize)
+	}
+
+	// The authorization event must name the uploaded blob, which is only
+	// known once the body has been hashed.
+	verify := func(hash string) error {
+		if _, err := authorize(r, "upload", hash); err != nil {
+			return authError{err}
+		}
+		return nil
+	}
+	blob, err := s.store.Put(r.Context(), body, pubkey, r.Header.Get("Content-Type"), verify)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxErr):
+			fail(w, http.StatusRequestEntityTooLarge, "blob exceeds the maximum upload size")
+		case isAuthError(err):
+			fail(w, http.StatusUnauthorized, err.Error())
+		default:
+			log.Printf("Failed to store blob: %v", err)
+			fail(w, http.StatusInternalServerError, "failed to store blob")
+		}
+		return
+	}
+
+	writeJSON(w, s.describe(r, blob))
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
+	hash, ok := parseBlobName(r.PathValue("blob"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Authorize before touching the store so unauthenticated callers
+	// cannot probe which private blobs exist.
+	var pubkey string
+	if !s.opts.PublicRead {
+		var err error
+		pubkey, err = authorize(r, "get", hash)
+		if err != nil {
+			fail(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+	}
+
+	blob, err := s.store.Get(r.Context(), hash)
+	if err == nil && !s.opts.PublicRead && !blob.OwnedBy(pubkey) {
+		err = ErrNotFound
+	}
+	if errors.Is(err, ErrNotFound) {
+		fail(w, http.StatusNotFound, "blob not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to look up blob %s: %v", hash, err)
+		fail(w, http.StatusInternalServerError, "failed to look up blob")
+		return
+	}
+
+	content, err := s.store.Open(r.Context(), hash)
+	if errors.Is(err, ErrNotFound) {
+		fail(w, http.StatusNotFound, "blob not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to open blob %s: %v", hash, err)
+		fail(w, http.StatusInternalServerError, "failed to read blob")
+		return
+	}
+	defer content.Close()
+
+	// Blobs are served from the relay's own origin, so never let a browser
+	// render uploaded markup or script there.
+	contentType := blob.Type
+	if !inlineSafe(contentType) {
+		contentType = "application/octet-stream"
+		w.Header().Set("Content-Disposition", "attachment")
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	w.Header().Set("Cache-Control", "private, max-age=31536000, immutable")
+	http.ServeContent(w, r, "", time.Unix(blob.Uploaded, 0), content)
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
+	hash, ok := parseBlobName(r.PathValue("blob"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	pubkey, err := authorize(r, "delete", hash)
+	if err != nil {
+		fail(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	err = s.store.Delete(r.Context(), hash, pubkey)
+	if errors.Is(err, ErrNotFound) {
+		fail(w, http.StatusNotFound, "blob not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to delete blob %s: %v", hash, err)
+		fail(w, http.StatusInternalServerError, "failed to delete blob")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	pubkey := r.PathValue("pubkey")
+	if !httpauth.IsHex32(pubkey) {
+		fail(w, http.StatusBadRequest, "invalid pubkey")
+		return
+	}
+
+	if !s.opts.PublicRead {
+		signer, err := authorize(r, "list", "")
+		if err != nil {
+			fail(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		if signer != pubkey {
+			fail(w, http.StatusForbidden, "blobs can only be listed by their owner")
+			return
+		}
+	}
+
+	since, err := parseTimestamp(r.URL.Query().Get("since"))
+	if err != nil {
+		fail(w, http.StatusBadRequest, "invalid since")
+		return
+	}
+	until, err := parseTimestamp(r.URL.Query().Get("until"))
+	if err != nil {
+		fail(w, http.StatusBadRequest, "invalid until")
+		return
+	}
+
+	blobs, err := s.store.List(r.Context(), pubkey)
+	if err != nil {
+		log.Printf("Failed to list blobs for %s: %v", pubkey, err)
+		fail(w, http.StatusInternalServerError, "failed to list blobs")
+		return
+	}
+	sort.Slice(blobs, func(i, j int) bool { return blobs[i].Uploaded > blobs[j].Uploaded })
+
+	descriptors := make([]descriptor, 0, len(blobs))
+	for _, blob := range blobs {
+		if (since != 0 && blob.Uploaded < since) || (until != 0 && blob.Uploaded > until) {
+			continue
+		}
+		descriptors = append(descriptors, s.describe(r, blob))
+	}
+	writeJSON(w, descriptors)
+}
+
+// cors adds the headers BUD-01 requires so browser clients can reach the
+// server from any origin.
+func (s *Server) cors(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Headers", "Authorization, *")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, PUT, DELETE")
+		w.Header().Set("Access-Control-Max-Age", "86400")
+		next(w, r)
+	}
+}
+
+func (s *Server) describe(r *http.Request, blob *Blob) descriptor {
+	return descriptor{
+		URL:      s.baseURL(r) + "/" + blob.SHA256,
+		SHA256:   blob.SHA256,
+		Size:     blob.Size,
+		Type:     blob.Type,
+		Uploaded: blob.Uploaded,
+	}
+}
+
+func (s *Server) baseURL(r *http.Request) string {
+	if s.opts.BaseURL != "" {
+		return strings.TrimSuffix(s.opts.BaseURL, "/")
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// authError marks failures from the upload verify callback.
+type authError struct{ error }
+
+func isAuthError(err error) bool {
+	var ae authError
+	return errors.As(err, &ae)
+}
+
+// parseBlobName accepts "<sha256>" or "<sha256>.<ext>" and returns the
+// hash.
+func parseBlobName(name string) (string, bool) {
+	hash := strings.TrimSuffix(name, path.Ext(name))
+	return hash, httpauth.IsHex32(hash)
+}
+
+// inlineSafe reports whether a blob of contentType can be displayed inline.
+// Anything that may carry script, such as HTML, SVG, XML or JavaScript, is
+// left out.
+func inlineSafe(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch mediaType {
+	case "image/svg+xml":
+		return false
+	case "text/plain", "application/json", "application/pdf":
+		return true
+	}
+	major, _, _ := strings.Cut(mediaType, "/")
+	return major == "image" || major == "audio" || major == "video"
+}
+
+func parseTimestamp(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
+
+// fail writes an error response, mirroring the reason in the X-Reason
+// header as Blossom clients expect.
+func fail(w http.ResponseWriter, status int, reason string) {
+	w.Header().Set("X-Reason", reason)
+	http.Error(w, reason, status)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Failed to write response: %v", err)
+	}
+}
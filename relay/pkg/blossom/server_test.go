@@ -0,0 +1,318 @@
+package blossom
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func newTestServer(t *testing.T, opts Options) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	NewServer(newDiskStore(t), opts).Register(mux)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// upload stores body as sk and returns its hash.
+func upload(t *testing.T, srv *httptest.Server, sk, body string) string {
+	t.Helper()
+	hash := sha256Hex(body)
+	resp := doRequest(t, "PUT", srv.URL+"/upload", blobAuth(t, sk, "upload", hash), strings.NewReader(body))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("upload status = %d (%s)", resp.StatusCode, resp.Header.Get("X-Reason"))
+	}
+	return hash
+}
+
+func doRequest(t *testing.T, method, url, auth string, body io.Reader) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestUpload(t *testing.T) {
+	sk := nostr.GeneratePrivateKey()
+	pubkey, err := nostr.GetPublicKey(sk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := newTestServer(t, Options{MaxUploadSize: 16, Allow: []string{pubkey}})
+	body := "health data"
+	hash := sha256Hex(body)
+
+	tests := []struct {
+		name   string
+		auth   string
+		body   io.Reader
+		status int
+	}{
+		{"no authorization", "", strings.NewReader(body), http.StatusUnauthorized},
+		{"wrong verb", blobAuth(t, sk, "delete", hash), strings.NewReader(body), http.StatusUnauthorized},
+		{"pubkey not allowed", blobAuth(t, nostr.GeneratePrivateKey(), "upload", hash), strings.NewReader(body), http.StatusForbidden},
+		{"x does not match body", blobAuth(t, sk, "upload", sha256Hex("other")), strings.NewReader(body), http.StatusUnauthorized},
+		{"declared length too large", blobAuth(t, sk, "upload", sha256Hex(strings.Repeat("a", 17))), strings.NewReader(strings.Repeat("a", 17)), http.StatusRequestEntityTooLarge},
+		// A plain io.Reader is sent chunked, so only MaxBytesReader catches it.
+		{"streamed body too large", blobAuth(t, sk, "upload", sha256Hex(strings.Repeat("a", 17))), io.MultiReader(strings.NewReader(strings.Repeat("a", 17))), http.StatusRequestEntityTooLarge},
+		{"valid", blobAuth(t, sk, "upload", hash), strings.NewReader(body), http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := doRequest(t, "PUT", srv.URL+"/upload", tt.auth, tt.body)
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status = %d (%s), want %d", resp.StatusCode, resp.Header.Get("X-Reason"), tt.status)
+			}
+			if tt.status != http.StatusOK {
+				if resp.Header.Get("X-Reason") == "" {
+					t.Error("error response has no X-Reason header")
+				}
+				return
+			}
+
+			var desc descriptor
+			if err := json.NewDecoder(resp.Body).Decode(&desc); err != nil {
+				t.Fatal(err)
+			}
+			if desc.SHA256 != hash || desc.Size != int64(len(body)) || desc.URL != srv.URL+"/"+hash {
+				t.Errorf("descriptor = %+v", desc)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	owner := nostr.GeneratePrivateKey()
+	coOwner := nostr.GeneratePrivateKey()
+	stranger := nostr.GeneratePrivateKey()
+	srv := newTestServer(t, Options{})
+
+	hash := upload(t, srv, owner, "shared")
+	upload(t, srv, coOwner, "shared")
+
+	// The steps run in order against the same store.
+	steps := []struct {
+		name   string
+		auth   string
+		status int
+	}{
+		{"no authorization", "", http.StatusUnauthorized},
+		{"wrong verb", blobAuth(t, owner, "upload", hash), http.StatusUnauthorized},
+		{"non-owner", blobAuth(t, stranger, "delete", hash), http.StatusNotFound},
+		{"owner", blobAuth(t, owner, "delete", hash), http.StatusNoContent},
+		{"owner again", blobAuth(t, owner, "delete", hash), http.StatusNotFound},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			resp := doRequest(t, "DELETE", srv.URL+"/"+hash, step.auth, nil)
+			if resp.StatusCode != step.status {
+				t.Fatalf("status = %d (%s), want %d", resp.StatusCode, resp.Header.Get("X-Reason"), step.status)
+			}
+		})
+	}
+
+	if resp := doRequest(t, "GET", srv.URL+"/"+hash, blobAuth(t, coOwner, "get", hash), nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("other owner's get after delete status = %d, want 200", resp.StatusCode)
+	}
+	if resp := doRequest(t, "GET", srv.URL+"/"+hash, blobAuth(t, owner, "get", hash), nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("deleting owner's get after delete status = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestList(t *testing.T) {
+	owner := nostr.GeneratePrivateKey()
+	pubkey, err := nostr.GetPublicKey(owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stranger := nostr.GeneratePrivateKey()
+	srv := newTestServer(t, Options{})
+
+	upload(t, srv, owner, "first")
+	upload(t, srv, owner, "second")
+	upload(t, srv, stranger, "not listed")
+
+	now := time.Now().Unix()
+	past := strconv.FormatInt(now-3600, 10)
+	future := strconv.FormatInt(now+3600, 10)
+
+	tests := []struct {
+		name   string
+		path   string
+		auth   string
+		status int
+		count  int
+	}{
+		{"no authorization", "/list/" + pubkey, "", http.StatusUnauthorized, 0},
+		{"signer is not pubkey", "/list/" + pubkey, blobAuth(t, stranger, "list"), http.StatusForbidden, 0},
+		{"bad pubkey", "/list/npub1", blobAuth(t, owner, "list"), http.StatusBadRequest, 0},
+		{"bad since", "/list/" + pubkey + "?since=yesterday", blobAuth(t, owner, "list"), http.StatusBadRequest, 0},
+		{"bad until", "/list/" + pubkey + "?until=1.5", blobAuth(t, owner, "list"), http.StatusBadRequest, 0},
+		{"all", "/list/" + pubkey, blobAuth(t, owner, "list"), http.StatusOK, 2},
+		{"since past", "/list/" + pubkey + "?since=" + past, blobAuth(t, owner, "list"), http.StatusOK, 2},
+		{"since future", "/list/" + pubkey + "?since=" + future, blobAuth(t, owner, "list"), http.StatusOK, 0},
+		{"until past", "/list/" + pubkey + "?until=" + past, blobAuth(t, owner, "list"), http.StatusOK, 0},
+		{"between", "/list/" + pubkey + "?since=" + past + "&until=" + future, blobAuth(t, owner, "list"), http.StatusOK, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := doRequest(t, "GET", srv.URL+tt.path, tt.auth, nil)
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status = %d (%s), want %d", resp.StatusCode, resp.Header.Get("X-Reason"), tt.status)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var descs []descriptor
+			if err := json.NewDecoder(resp.Body).Decode(&descs); err != nil {
+				t.Fatal(err)
+			}
+			if len(descs) != tt.count {
+				t.Errorf("got %d descriptors, want %d", len(descs), tt.count)
+			}
+		})
+	}
+}
+
+func TestPreflight(t *testing.T) {
+	srv := newTestServer(t, Options{})
+
+	for _, path := range []string{"/upload", "/list/" + testHash, "/" + testHash} {
+		t.Run(path, func(t *testing.T) {
+			resp := doRequest(t, "OPTIONS", srv.URL+path, "", nil)
+			if resp.StatusCode != http.StatusNoContent {
+				t.Fatalf("status = %d, want 204", resp.StatusCode)
+			}
+			for header, want := range map[string]string{
+				"Access-Control-Allow-Origin":  "*",
+				"Access-Control-Allow-Headers": "Authorization, *",
+				"Access-Control-Allow-Methods": "GET, HEAD, PUT, DELETE",
+			} {
+				if got := resp.Header.Get(header); got != want {
+					t.Errorf("%s = %q, want %q", header, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestGetDoesNotRevealExistence(t *testing.T) {
+	owner := nostr.GeneratePrivateKey()
+	stranger := nostr.GeneratePrivateKey()
+	srv := newTestServer(t, Options{})
+
+	body := "private"
+	hash := sha256Hex(body)
+	missing := sha256Hex("missing")
+	if resp := doRequest(t, "PUT", srv.URL+"/upload", blobAuth(t, owner, "upload", hash), strings.NewReader(body)); resp.StatusCode != http.StatusOK {
+		t.Fatalf("upload status = %d", resp.StatusCode)
+	}
+
+	for _, method := range []string{"GET", "HEAD"} {
+		existing := doRequest(t, method, srv.URL+"/"+hash, "", nil)
+		absent := doRequest(t, method, srv.URL+"/"+missing, "", nil)
+		if existing.StatusCode != http.StatusUnauthorized || absent.StatusCode != http.StatusUnauthorized {
+			t.Errorf("%s without auth: existing = %d, missing = %d, want 401 for both", method, existing.StatusCode, absent.StatusCode)
+		}
+	}
+
+	notOwned := doRequest(t, "GET", srv.URL+"/"+hash, blobAuth(t, stranger, "get"), nil)
+	absent := doRequest(t, "GET", srv.URL+"/"+missing, blobAuth(t, stranger, "get"), nil)
+	if notOwned.StatusCode != http.StatusNotFound || absent.StatusCode != http.StatusNotFound {
+		t.Errorf("non-owner: not owned = %d, missing = %d, want 404 for both", notOwned.StatusCode, absent.StatusCode)
+	}
+
+	resp := doRequest(t, "GET", srv.URL+"/"+hash+".txt", blobAuth(t, owner, "get"), nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("owner get without x status = %d (%s)", resp.StatusCode, resp.Header.Get("X-Reason"))
+	}
+	if data, _ := io.ReadAll(resp.Body); string(data) != body {
+		t.Errorf("body = %q, want %q", data, body)
+	}
+}
+
+func TestGetContentType(t *testing.T) {
+	sk := nostr.GeneratePrivateKey()
+	srv := newTestServer(t, Options{PublicRead: true})
+
+	tests := []struct {
+		uploadType  string
+		contentType string
+		attachment  bool
+	}{
+		{"image/png", "image/png", false},
+		{"text/plain; charset=utf-8", "text/plain; charset=utf-8", false},
+		{"", "application/octet-stream", true},
+		{"text/html", "application/octet-stream", true},
+		{"image/svg+xml", "application/octet-stream", true},
+		{"application/xhtml+xml", "application/octet-stream", true},
+		{"text/javascript", "application/octet-stream", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uploadType, func(t *testing.T) {
+			// Vary the body so each case stores its own blob and type.
+			body := "<script>alert(1)</script>" + tt.uploadType
+			hash := sha256Hex(body)
+			req, err := http.NewRequest("PUT", srv.URL+"/upload", strings.NewReader(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", blobAuth(t, sk, "upload", hash))
+			if tt.uploadType != "" {
+				req.Header.Set("Content-Type", tt.uploadType)
+			}
+			put, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			put.Body.Close()
+			if put.StatusCode != http.StatusOK {
+				t.Fatalf("upload status = %d (%s)", put.StatusCode, put.Header.Get("X-Reason"))
+			}
+
+			resp := doRequest(t, "GET", srv.URL+"/"+hash, "", nil)
+			if got := resp.Header.Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+			if got := resp.Header.Get("Content-Disposition") == "attachment"; got != tt.attachment {
+				t.Errorf("attachment = %v, want %v", got, tt.attachment)
+			}
+			if got := resp.Header.Get("X-Content-Type-Options"); got != "nosniff" {
+				t.Errorf("X-Content-Type-Options = %q, want nosniff", got)
+			}
+			if got := resp.Header.Get("Content-Security-Policy"); got != "sandbox" {
+				t.Errorf("Content-Security-Policy = %q, want sandbox", got)
+			}
+		})
+	}
+}
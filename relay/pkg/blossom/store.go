@@ -0,0 +1,54 @@
+// Package blossom implements an embedded Blossom media server (BUD-01 and
+// BUD-02) so small deployments can keep private health blobs next to the
+// relay without running a separate node.
+package blossom
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned when a blob does not exist, or is not owned by
+// the pubkey an operation is scoped to.
+var ErrNotFound = errors.New("blob not found")
+
+// Blob describes a stored blob and the pubkeys that uploaded it.
+type Blob struct {
+	SHA256   string   `json:"sha256"`
+	Size     int64    `json:"size"`
+	Type     string   `json:"type,omitempty"`
+	Uploaded int64    `json:"uploaded"`
+	Owners   []string `json:"owners"`
+}
+
+// OwnedBy reports whether pubkey has uploaded the blob.
+func (b *Blob) OwnedBy(pubkey string) bool {
+	for _, owner := range b.Owners {
+		if owner == pubkey {
+			return true
+		}
+	}
+	return false
+}
+
+// Store persists blobs by their SHA-256 hash along with their owners.
+type Store interface {
+	// Put stores the blob read from r on behalf of owner. verify is called
+	// with the blob's hex SHA-256 before it is committed; a non-nil error
+	// aborts the upload and is returned unchanged.
+	Put(ctx context.Context, r io.Reader, owner, contentType string, verify func(hash string) error) (*Blob, error)
+
+	// Get returns the descriptor for a blob.
+	Get(ctx context.Context, hash string) (*Blob, error)
+
+	// Open returns the blob's content.
+	Open(ctx context.Context, hash string) (io.ReadSeekCloser, error)
+
+	// Delete removes owner from the blob, dropping the content once no
+	// owners remain.
+	Delete(ctx context.Context, hash, owner string) error
+
+	// List returns the blobs uploaded by owner.
+	List(ctx context.Context, owner string) ([]*Blob, error)
+}
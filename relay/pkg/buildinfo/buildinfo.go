@@ -0,0 +1,62 @@
+// Package buildinfo reports the version of the running relay binary.
+//
+// Release builds set the variables below with -ldflags, for example:
+//
+//	go build -ldflags "-X github.com/HealthNoteLabs/HealthNote-Relay/relay/pkg/buildinfo.Version=v0.2.0 \
+//	  -X github.com/HealthNoteLabs/HealthNote-Relay/relay/pkg/buildinfo.Commit=$(git rev-parse HEAD) \
+//	  -X github.com/HealthNoteLabs/HealthNote-Relay/relay/pkg/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/relay
+//
+// Builds without ldflags fall back to the VCS stamp the Go toolchain
+// embeds.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Set at build time via -ldflags -X.
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+// Info describes the running binary.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildTime string `json:"build_time,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build information for the running binary.
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = s.Value
+			}
+		case "vcs.time":
+			if info.BuildTime == "" {
+				info.BuildTime = s.Value
+			}
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+	return info
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Addrs is a list of listen addresses. In YAML it may be written as a
+// single string or a sequence; in the environment as a comma-separated
+// list.
+type Addrs []string
+
+// UnmarshalYAML accepts either a scalar or a sequence of addresses.
+func (a *Addrs) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		*a = Addrs{node.Value}
+		return nil
+	case yaml.SequenceNode:
+		var list []string
+		if err := node.Decode(&list); err != nil {
+			return err
+		}
+		*a = list
+		return nil
+	}
+	return fmt.Errorf("line %d: listen must be an address or a list of addresses", node.Line)
+}
+
+func parseAddrs(s string) Addrs {
+	return Addrs(splitList(s))
+}
+
+// splitList splits a comma-separated environment value, dropping empty
+// entries.
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestAddrsUnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		doc      string
+		expected Addrs
+		wantErr  bool
+	}{
+		{"scalar", `listen: ":8080"`, Addrs{":8080"}, false},
+		{"sequence", "listen:\n  - \"0.0.0.0:8080\"\n  - \"[::]:8080\"", Addrs{"0.0.0.0:8080", "[::]:8080"}, false},
+		{"mapping", "listen: {addr: \":8080\"}", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc struct {
+				Listen Addrs `yaml:"listen"`
+			}
+			err := yaml.Unmarshal([]byte(tt.doc), &doc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(doc.Listen, tt.expected) {
+				t.Errorf("Listen = %q, want %q", doc.Listen, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseAddrs(t *testing.T) {
+	got := parseAddrs(" :8080, [::1]:9000,,")
+	expected := Addrs{":8080", "[::1]:9000"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("parseAddrs() = %q, want %q", got, expected)
+	}
+}
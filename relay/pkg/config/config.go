@@ -0,0 +1,264 @@
+// Package config loads relay settings from an optional YAML file and the
+// environment.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/HealthNoteLabs/HealthNote-Relay/relay/pkg/httpauth"
+)
+
+// Config holds the relay's runtime settings.
+type Config struct {
+	Profile     Profile   `yaml:"-"`
+	Verbose     bool      `yaml:"verbose"`
+	Listen      Addrs     `yaml:"listen"`
+	ReusePort   bool      `yaml:"reuse_port"`
+	DatabaseURL string    `yaml:"database_url"`
+	TLS         TLS       `yaml:"tls"`
+	Blossom     Blossom   `yaml:"blossom"`
+	Relay       RelayInfo `yaml:"relay"`
+}
+
+// TLS configures native TLS, either from certificate files or from
+// certificates obtained automatically via ACME.
+type TLS struct {
+	CertFile string   `yaml:"cert_file"`
+	KeyFile  string   `yaml:"key_file"`
+	Autocert Autocert `yaml:"autocert"`
+}
+
+// Autocert configures automatic certificates from Let's Encrypt, answered
+// with the TLS-ALPN-01 challenge on the relay's own listener.
+type Autocert struct {
+	Hostname string `yaml:"hostname"`
+	CacheDir string `yaml:"cache_dir"`
+	Email    string `yaml:"email"`
+}
+
+// Enabled reports whether the relay should serve TLS.
+func (t TLS) Enabled() bool {
+	return t.CertFile != "" || t.Autocert.Hostname != ""
+}
+
+// Blossom configures the embedded Blossom media server.
+type Blossom struct {
+	Enabled       bool     `yaml:"enabled"`
+	Dir           string   `yaml:"dir"`
+	BaseURL       string   `yaml:"base_url"`
+	MaxUploadSize int64    `yaml:"max_upload_size"`
+	PublicRead    bool     `yaml:"public_read"`
+	Allow         []string `yaml:"allow"`
+}
+
+// RelayInfo describes the relay to clients and operators.
+type RelayInfo struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Pubkey      string `yaml:"pubkey"`
+	Contact     string `yaml:"contact"`
+}
+
+// Defaults returns the settings used for anything the config file and
+// environment leave unset.
+func Defaults(profile Profile) *Config {
+	return &Config{
+		Profile: profile,
+		Verbose: profile.Verbose(),
+		Listen:  Addrs{":8080"},
+		TLS: TLS{
+			Autocert: Autocert{CacheDir: "autocert-cache"},
+		},
+		Blossom: Blossom{
+			Dir:           "blobs",
+			MaxUploadSize: 100 << 20,
+		},
+		Relay: RelayInfo{
+			Name:        "Health & Fitness Relay",
+			Description: "A specialized Nostr relay for health and fitness data",
+		},
+	}
+}
+
+// Load reads the YAML file at path, if path is non-empty, on top of the
+// profile defaults and then applies environment overrides. The profile is
+// taken from RELAY_ENV, falling back to the file's env key.
+func Load(path string) (*Config, error) {
+	var data []byte
+	if path != "" {
+		var err error
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config: %w", err)
+		}
+	}
+
+	var probe struct {
+		Env string `yaml:"env"`
+	}
+	if err := yaml.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	env := probe.Env
+	if v := os.Getenv("RELAY_ENV"); v != "" {
+		env = v
+	}
+	profile, err := ParseProfile(env)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := Defaults(profile)
+	if err := decodeStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	if err := cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate reports settings that would prevent the relay from starting.
+func (c *Config) Validate() error {
+	if len(c.Listen) == 0 {
+		return errors.New("at least one listen address is required")
+	}
+	for _, addr := range c.Listen {
+		if addr == "" {
+			return errors.New("listen addresses must not be empty")
+		}
+	}
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		return errors.New("tls.cert_file and tls.key_file must be set together")
+	}
+	if c.TLS.CertFile != "" && c.TLS.Autocert.Hostname != "" {
+		return errors.New("tls.cert_file and tls.autocert.hostname are mutually exclusive")
+	}
+	if c.TLS.Autocert.Hostname != "" && c.TLS.Autocert.CacheDir == "" {
+		return errors.New("tls.autocert.cache_dir must be set when autocert is enabled")
+	}
+	if c.Blossom.Enabled && c.Blossom.Dir == "" {
+		return errors.New("blossom.dir must be set when the Blossom server is enabled")
+	}
+	if c.Blossom.Enabled && len(c.Blossom.Allow) == 0 {
+		return errors.New("blossom.allow must list the pubkeys permitted to upload")
+	}
+	for _, pubkey := range c.Blossom.Allow {
+		if !httpauth.IsHex32(pubkey) {
+			return fmt.Errorf("blossom.allow: %q is not a hex pubkey", pubkey)
+		}
+	}
+	if c.Profile.Strict() {
+		if err := checkDatabasePassword(c.DatabaseURL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// weakPasswords are credentials from example configs and image defaults
+// that must never reach a production database.
+var weakPasswords = map[string]bool{
+	"password": true,
+	"postgres": true,
+	"changeme": true,
+}
+
+func checkDatabasePassword(databaseURL string) error {
+	if databaseURL == "" {
+		return nil
+	}
+	u, err := url.Parse(databaseURL)
+	if err != nil {
+		return errors.New("database_url is not a valid URL; special characters in the password must be percent-encoded")
+	}
+	password, ok := u.User.Password()
+	if !ok || password == "" {
+		return nil
+	}
+	if weakPasswords[strings.ToLower(password)] {
+		return errors.New("database_url uses a default password, which the prod profile does not allow")
+	}
+	return nil
+}
+
+func (c *Config) applyEnv() error {
+	for key, dst := range map[string]*bool{
+		"RELAY_VERBOSE":             &c.Verbose,
+		"RELAY_REUSE_PORT":          &c.ReusePort,
+		"RELAY_BLOSSOM_ENABLED":     &c.Blossom.Enabled,
+		"RELAY_BLOSSOM_PUBLIC_READ": &c.Blossom.PublicRead,
+	} {
+		if err := setBool(dst, key); err != nil {
+			return err
+		}
+	}
+	if v := os.Getenv("RELAY_LISTEN"); v != "" {
+		c.Listen = parseAddrs(v)
+	}
+	setString(&c.DatabaseURL, "DATABASE_URL")
+	setString(&c.TLS.CertFile, "RELAY_TLS_CERT")
+	setString(&c.TLS.KeyFile, "RELAY_TLS_KEY")
+	setString(&c.TLS.Autocert.Hostname, "RELAY_AUTOCERT_HOST")
+	setString(&c.TLS.Autocert.CacheDir, "RELAY_AUTOCERT_CACHE")
+	setString(&c.TLS.Autocert.Email, "RELAY_AUTOCERT_EMAIL")
+	setString(&c.Blossom.Dir, "RELAY_BLOSSOM_DIR")
+	setString(&c.Blossom.BaseURL, "RELAY_BLOSSOM_BASE_URL")
+	if v := os.Getenv("RELAY_BLOSSOM_ALLOW"); v != "" {
+		c.Blossom.Allow = splitList(v)
+	}
+	setString(&c.Relay.Name, "RELAY_NAME")
+	setString(&c.Relay.Description, "RELAY_DESCRIPTION")
+	setString(&c.Relay.Pubkey, "RELAY_PUBKEY")
+	setString(&c.Relay.Contact, "RELAY_CONTACT")
+	return nil
+}
+
+// decodeStrict decodes data into cfg, rejecting unknown keys so typos in
+// the config file are not silently ignored. The env key is consumed by
+// Load and skipped here.
+func decodeStrict(data []byte, cfg *Config) error {
+	var doc struct {
+		Env     string `yaml:"env"`
+		*Config `yaml:",inline"`
+	}
+	doc.Config = cfg
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&doc); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+func setString(dst *string, key string) {
+	if v := os.Getenv(key); v != "" {
+		*dst = v
+	}
+}
+
+func setBool(dst *bool, key string) error {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %w", key, v, err)
+	}
+	*dst = b
+	return nil
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "relay.yaml")
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadProfile(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		env      string
+		expected Profile
+	}{
+		{"default", "", "", ProfileDev},
+		{"file", "env: prod\n", "", ProfileProd},
+		{"empty env keeps file", "env: staging\n", "", ProfileStaging},
+		{"env overrides file", "env: prod\n", "dev", ProfileDev},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("RELAY_ENV", tt.env)
+			cfg, err := Load(writeConfig(t, tt.file))
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if cfg.Profile != tt.expected {
+				t.Errorf("profile = %s, want %s", cfg.Profile, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	// Every variable a case sets is cleared first so cases do not leak
+	// into each other or pick up the caller's environment.
+	envKeys := []string{"RELAY_ENV", "RELAY_LISTEN", "RELAY_NAME", "RELAY_VERBOSE", "RELAY_REUSE_PORT", "RELAY_BLOSSOM_ENABLED"}
+
+	tests := []struct {
+		name    string
+		file    string
+		noFile  bool
+		env     map[string]string
+		wantErr bool
+		check   func(t *testing.T, cfg *Config)
+	}{
+		{name: "unknown key", file: "lisen: \":9000\"\n", wantErr: true},
+		{name: "unknown nested key", file: "blossom:\n  enabeld: true\n", wantErr: true},
+		{
+			name: "file values",
+			file: "listen: \":9000\"\nverbose: false\nrelay:\n  name: From file\n",
+			check: func(t *testing.T, cfg *Config) {
+				if !reflect.DeepEqual(cfg.Listen, Addrs{":9000"}) || cfg.Verbose || cfg.Relay.Name != "From file" {
+					t.Errorf("listen = %v, verbose = %v, name = %q", cfg.Listen, cfg.Verbose, cfg.Relay.Name)
+				}
+			},
+		},
+		{
+			name: "env overrides file strings",
+			file: "listen: \":9000\"\nrelay:\n  name: From file\n",
+			env:  map[string]string{"RELAY_LISTEN": ":7000, :7001", "RELAY_NAME": "From env"},
+			check: func(t *testing.T, cfg *Config) {
+				if !reflect.DeepEqual(cfg.Listen, Addrs{":7000", ":7001"}) || cfg.Relay.Name != "From env" {
+					t.Errorf("listen = %v, name = %q", cfg.Listen, cfg.Relay.Name)
+				}
+			},
+		},
+		{
+			name: "env overrides file bools",
+			file: "verbose: true\nreuse_port: false\n",
+			env:  map[string]string{"RELAY_VERBOSE": "false", "RELAY_REUSE_PORT": "true"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Verbose || !cfg.ReusePort {
+					t.Errorf("verbose = %v, reuse_port = %v", cfg.Verbose, cfg.ReusePort)
+				}
+			},
+		},
+		{name: "invalid RELAY_VERBOSE", env: map[string]string{"RELAY_VERBOSE": "yes"}, wantErr: true},
+		{name: "invalid RELAY_BLOSSOM_ENABLED", env: map[string]string{"RELAY_BLOSSOM_ENABLED": "on"}, wantErr: true},
+		{
+			name: "empty file",
+			check: func(t *testing.T, cfg *Config) {
+				if !reflect.DeepEqual(cfg, Defaults(ProfileDev)) {
+					t.Errorf("config = %+v, want defaults", cfg)
+				}
+			},
+		},
+		{
+			name:   "no file",
+			noFile: true,
+			check: func(t *testing.T, cfg *Config) {
+				if !reflect.DeepEqual(cfg, Defaults(ProfileDev)) {
+					t.Errorf("config = %+v, want defaults", cfg)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range envKeys {
+				t.Setenv(key, "")
+			}
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			path := writeConfig(t, tt.file)
+			if tt.noFile {
+				path = ""
+			}
+
+			cfg, err := Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.check != nil {
+				tt.check(t, cfg)
+			}
+		})
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Load() of a missing file succeeded")
+	}
+}
+
+func TestValidateDatabasePassword(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile Profile
+		url     string
+		wantErr bool
+	}{
+		{"prod default password", ProfileProd, "postgres://user:password@db/relay", true},
+		{"prod default password mixed case", ProfileProd, "postgres://user:Postgres@db/relay", true},
+		{"prod strong password", ProfileProd, "postgres://user:s3cr3t-Value@db/relay", false},
+		{"prod no password", ProfileProd, "postgres://user@db/relay", false},
+		{"dev default password", ProfileDev, "postgres://user:password@db/relay", false},
+		{"prod unencoded password", ProfileProd, "postgres://user:100%@db/relay", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Defaults(tt.profile)
+			cfg.DatabaseURL = tt.url
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateBlossomAllow(t *testing.T) {
+	const pubkey = "79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+	tests := []struct {
+		name    string
+		enabled bool
+		allow   []string
+		wantErr bool
+	}{
+		{"disabled without allow", false, nil, false},
+		{"enabled without allow", true, nil, true},
+		{"enabled with allow", true, []string{pubkey}, false},
+		{"npub instead of hex", true, []string{"npub10elfcs4fr0l0r8af98jlmgdh9c8tcxjvz9qkw038js35mp4dma8qzvjptg"}, true},
+		{"uppercase hex", true, []string{strings.ToUpper(pubkey)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Defaults(ProfileDev)
+			cfg.Blossom.Enabled = tt.enabled
+			cfg.Blossom.Allow = tt.allow
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Profile selects a set of defaults for a deployment environment.
+type Profile string
+
+const (
+	ProfileDev     Profile = "dev"
+	ProfileStaging Profile = "staging"
+	ProfileProd    Profile = "prod"
+)
+
+// ParseProfile parses a RELAY_ENV value. An empty value selects ProfileDev.
+func ParseProfile(s string) (Profile, error) {
+	switch Profile(strings.ToLower(strings.TrimSpace(s))) {
+	case "", ProfileDev, "development":
+		return ProfileDev, nil
+	case ProfileStaging:
+		return ProfileStaging, nil
+	case ProfileProd, "production":
+		return ProfileProd, nil
+	}
+	return "", fmt.Errorf("unknown RELAY_ENV %q (want dev, staging or prod)", s)
+}
+
+// Strict reports whether the profile rejects unsafe settings that other
+// profiles tolerate, such as default database passwords.
+func (p Profile) Strict() bool {
+	return p == ProfileProd
+}
+
+// Verbose reports whether the profile enables verbose logging by default.
+func (p Profile) Verbose() bool {
+	return p == ProfileDev
+}
@@ -0,0 +1,205 @@
+// Package httpauth authenticates HTTP requests with NIP-98 signed events.
+package httpauth
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// KindHTTPAuth is the NIP-98 HTTP authorization event kind.
+const KindHTTPAuth = 27235
+
+// DefaultMaxAge is how far an authorization event's created_at may be
+// from the server clock when Options.MaxAge is unset.
+const DefaultMaxAge = time.Minute
+
+// maxPayloadSize bounds the request body read to check a payload tag.
+const maxPayloadSize = 16 << 20
+
+// Options configures request verification.
+type Options struct {
+	// BaseURL is the public scheme and host the relay is reached at, used
+	// to rebuild the absolute URL the u tag must match. When empty it is
+	// derived from each request.
+	BaseURL string
+
+	// MaxAge overrides DefaultMaxAge.
+	MaxAge time.Duration
+
+	// Allow restricts access to these pubkeys. An empty list admits any
+	// pubkey with a valid authorization event.
+	Allow []string
+}
+
+// ErrForbidden is returned when a validly signed request comes from a
+// pubkey that is not in the allowlist.
+var ErrForbidden = errors.New("pubkey is not allowed")
+
+type contextKey struct{}
+
+// Pubkey returns the authenticated pubkey stored by Middleware.
+func Pubkey(ctx context.Context) (string, bool) {
+	pubkey, ok := ctx.Value(contextKey{}).(string)
+	return pubkey, ok
+}
+
+// Middleware rejects requests without a valid NIP-98 authorization and
+// passes the authenticated pubkey to next through the request context.
+func Middleware(opts Options) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			pubkey, err := Verify(r, opts)
+			if errors.Is(err, ErrForbidden) {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", "Nostr")
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, pubkey)))
+		})
+	}
+}
+
+// Verify checks r's NIP-98 authorization and returns the signing pubkey.
+// When the event carries a payload tag the request body is hashed and
+// then restored so handlers can still read it.
+func Verify(r *http.Request, opts Options) (string, error) {
+	evt, err := DecodeEvent(r)
+	if err != nil {
+		return "", err
+	}
+	if evt.Kind != KindHTTPAuth {
+		return "", errors.New("authorization event has the wrong kind")
+	}
+
+	maxAge := opts.MaxAge
+	if maxAge == 0 {
+		maxAge = DefaultMaxAge
+	}
+	if age := time.Since(evt.CreatedAt.Time()); age > maxAge || age < -maxAge {
+		return "", errors.New("authorization event is too old or too far in the future")
+	}
+
+	if tagValue(evt, "u") != requestURL(r, opts.BaseURL) {
+		return "", errors.New("authorization event is for a different URL")
+	}
+	if !strings.EqualFold(tagValue(evt, "method"), r.Method) {
+		return "", errors.New("authorization event is for a different method")
+	}
+	if payload := tagValue(evt, "payload"); payload != "" {
+		if err := checkPayload(r, payload); err != nil {
+			return "", err
+		}
+	}
+
+	if len(opts.Allow) > 0 && !contains(opts.Allow, evt.PubKey) {
+		return "", ErrForbidden
+	}
+	return evt.PubKey, nil
+}
+
+// DecodeEvent parses the "Authorization: Nostr <base64 event>" header
+// shared by NIP-98 and Blossom, and checks the event's id and signature.
+// Callers are responsible for validating its kind and tags.
+func DecodeEvent(r *http.Request) (*nostr.Event, error) {
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return nil, errors.New("missing authorization")
+	}
+	encoded, ok := strings.CutPrefix(header, "Nostr ")
+	if !ok {
+		return nil, errors.New("authorization scheme must be Nostr")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, errors.New("authorization is not valid base64")
+	}
+	var evt nostr.Event
+	if err := json.Unmarshal(data, &evt); err != nil {
+		return nil, errors.New("authorization is not a valid event")
+	}
+
+	if evt.GetID() != evt.ID {
+		return nil, errors.New("authorization event id does not match its content")
+	}
+	if ok, err := evt.CheckSignature(); err != nil || !ok {
+		return nil, errors.New("authorization event signature is invalid")
+	}
+	return &evt, nil
+}
+
+// IsHex32 reports whether s is 32 bytes of lowercase hex, the form of
+// Nostr pubkeys and event ids and of Blossom blob hashes.
+func IsHex32(s string) bool {
+	if len(s) != 64 || strings.ToLower(s) != s {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+func checkPayload(r *http.Request, want string) error {
+	if r.Body == nil {
+		return errors.New("authorization event has a payload but the request has no body")
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPayloadSize+1))
+	r.Body.Close()
+	if err != nil {
+		return errors.New("failed to read request body")
+	}
+	if len(body) > maxPayloadSize {
+		return errors.New("request body is too large to verify its payload")
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	sum := sha256.Sum256(body)
+	if hex.EncodeToString(sum[:]) != strings.ToLower(want) {
+		return errors.New("authorization payload does not match the request body")
+	}
+	return nil
+}
+
+// requestURL rebuilds the absolute URL the client signed.
+func requestURL(r *http.Request, baseURL string) string {
+	if baseURL == "" {
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		baseURL = scheme + "://" + r.Host
+	}
+	return strings.TrimSuffix(baseURL, "/") + r.URL.RequestURI()
+}
+
+func tagValue(evt *nostr.Event, name string) string {
+	for _, tag := range evt.Tags {
+		if len(tag) >= 2 && tag[0] == name {
+			return tag[1]
+		}
+	}
+	return ""
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,195 @@
+package httpauth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/HealthNoteLabs/HealthNote-Relay/relay/internal/nostrtest"
+)
+
+// authHeader signs a NIP-98 event for url and method with sk, adding a
+// payload tag when body is non-empty.
+func authHeader(t *testing.T, sk string, kind int, createdAt time.Time, url, method, body string) string {
+	t.Helper()
+	tags := nostr.Tags{{"u", url}, {"method", method}}
+	if body != "" {
+		sum := sha256.Sum256([]byte(body))
+		tags = append(tags, nostr.Tag{"payload", hex.EncodeToString(sum[:])})
+	}
+	return nostrtest.AuthHeader(t, sk, kind, createdAt, tags)
+}
+
+func TestVerify(t *testing.T) {
+	sk := nostr.GeneratePrivateKey()
+	pubkey, _ := nostr.GetPublicKey(sk)
+	now := time.Now()
+	const target = "https://relay.example.com/api/v1/events?kinds=1301"
+
+	tests := []struct {
+		name    string
+		target  string
+		method  string
+		body    string
+		header  string
+		opts    Options
+		wantErr bool
+	}{
+		{
+			name:   "valid",
+			target: target,
+			method: "GET",
+			header: authHeader(t, sk, KindHTTPAuth, now, target, "GET", ""),
+		},
+		{
+			name:    "missing header",
+			target:  target,
+			method:  "GET",
+			wantErr: true,
+		},
+		{
+			name:    "wrong kind",
+			target:  target,
+			method:  "GET",
+			header:  authHeader(t, sk, 24242, now, target, "GET", ""),
+			wantErr: true,
+		},
+		{
+			name:    "url mismatch",
+			target:  target,
+			method:  "GET",
+			header:  authHeader(t, sk, KindHTTPAuth, now, "https://relay.example.com/api/v1/events?kinds=1", "GET", ""),
+			wantErr: true,
+		},
+		{
+			name:    "method mismatch",
+			target:  target,
+			method:  "DELETE",
+			header:  authHeader(t, sk, KindHTTPAuth, now, target, "GET", ""),
+			wantErr: true,
+		},
+		{
+			name:    "too old",
+			target:  target,
+			method:  "GET",
+			header:  authHeader(t, sk, KindHTTPAuth, now.Add(-2*time.Minute), target, "GET", ""),
+			wantErr: true,
+		},
+		{
+			name:    "too far in the future",
+			target:  target,
+			method:  "GET",
+			header:  authHeader(t, sk, KindHTTPAuth, now.Add(2*time.Minute), target, "GET", ""),
+			wantErr: true,
+		},
+		{
+			name:   "wider age window",
+			target: target,
+			method: "GET",
+			header: authHeader(t, sk, KindHTTPAuth, now.Add(-2*time.Minute), target, "GET", ""),
+			opts:   Options{MaxAge: 5 * time.Minute},
+		},
+		{
+			name:    "behind a TLS proxy without base url",
+			target:  "http://relay.example.com/api/v1/events?kinds=1301",
+			method:  "GET",
+			header:  authHeader(t, sk, KindHTTPAuth, now, target, "GET", ""),
+			wantErr: true,
+		},
+		{
+			name:   "behind a TLS proxy with base url",
+			target: "http://relay.internal:8080/api/v1/events?kinds=1301",
+			method: "GET",
+			header: authHeader(t, sk, KindHTTPAuth, now, target, "GET", ""),
+			opts:   Options{BaseURL: "https://relay.example.com/"},
+		},
+		{
+			name:   "payload matches",
+			target: target,
+			method: "POST",
+			body:   `{"kind":1301}`,
+			header: authHeader(t, sk, KindHTTPAuth, now, target, "POST", `{"kind":1301}`),
+		},
+		{
+			name:    "payload mismatch",
+			target:  target,
+			method:  "POST",
+			body:    `{"kind":1}`,
+			header:  authHeader(t, sk, KindHTTPAuth, now, target, "POST", `{"kind":1301}`),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+
+			got, err := Verify(r, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got != pubkey {
+				t.Errorf("Verify() pubkey = %s, want %s", got, pubkey)
+			}
+			if body, _ := io.ReadAll(r.Body); string(body) != tt.body {
+				t.Errorf("body after Verify = %q, want %q", body, tt.body)
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	allowed := nostr.GeneratePrivateKey()
+	allowedPubkey, _ := nostr.GetPublicKey(allowed)
+	other := nostr.GeneratePrivateKey()
+	const target = "https://relay.example.com/admin/stats"
+
+	handler := Middleware(Options{Allow: []string{allowedPubkey}})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pubkey, ok := Pubkey(r.Context())
+		if !ok || pubkey != allowedPubkey {
+			t.Errorf("Pubkey() = %q, %v; want %s", pubkey, ok, allowedPubkey)
+		}
+	}))
+
+	tests := []struct {
+		name   string
+		header string
+		status int
+	}{
+		{"allowed pubkey", authHeader(t, allowed, KindHTTPAuth, time.Now(), target, "GET", ""), http.StatusOK},
+		{"pubkey not in allowlist", authHeader(t, other, KindHTTPAuth, time.Now(), target, "GET", ""), http.StatusForbidden},
+		{"invalid authorization", authHeader(t, allowed, KindHTTPAuth, time.Now(), target, "POST", ""), http.StatusUnauthorized},
+		{"no authorization", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", target, nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d (%s), want %d", w.Code, strings.TrimSpace(w.Body.String()), tt.status)
+			}
+			if tt.status == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") != "Nostr" {
+				t.Error("401 response is missing WWW-Authenticate: Nostr")
+			}
+		})
+	}
+}